@@ -7,6 +7,7 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -58,6 +59,46 @@ func updateAMDUsage(cpus map[string]int, _ bool) map[string]error {
 	return amdErrors
 }
 
+// updateAMDPower copies cached AMD GPU power draw/limit into power.
+func updateAMDPower(power map[string]PowerInfo) map[string]error {
+	amdLock.Lock()
+	defer amdLock.Unlock()
+	for k, v := range amdPower {
+		power[k] = v
+	}
+	return amdErrors
+}
+
+// updateAMDClock copies cached AMD GPU clock speeds into clocks.
+func updateAMDClock(clocks map[string]ClockInfo) map[string]error {
+	amdLock.Lock()
+	defer amdLock.Unlock()
+	for k, v := range amdClocks {
+		clocks[k] = v
+	}
+	return amdErrors
+}
+
+// updateAMDFan copies cached AMD GPU fan speed/duty into fans.
+func updateAMDFan(fans map[string]FanInfo) map[string]error {
+	amdLock.Lock()
+	defer amdLock.Unlock()
+	for k, v := range amdFans {
+		fans[k] = v
+	}
+	return amdErrors
+}
+
+// updateAMDPCIeRate copies cached AMD GPU PCIe throughput into pcie.
+func updateAMDPCIeRate(pcie map[string]PCIeInfo) map[string]error {
+	amdLock.Lock()
+	defer amdLock.Unlock()
+	for k, v := range amdPCIe {
+		pcie[k] = v
+	}
+	return amdErrors
+}
+
 func startAMD(vars map[string]string) error {
 	enabled := vars["amd"] == "true" || vars["amdgpu"] == "true"
 	disabled := vars["amd"] == "false" || vars["amdgpu"] == "false"
@@ -86,10 +127,19 @@ func startAMD(vars map[string]string) error {
 	amdTemps = make(map[string]int)
 	amdMems = make(map[string]MemoryInfo)
 	amdCpus = make(map[string]int)
+	amdPower = make(map[string]PowerInfo)
+	amdClocks = make(map[string]ClockInfo)
+	amdFans = make(map[string]FanInfo)
+	amdPCIe = make(map[string]PCIeInfo)
 
 	RegisterTemp(updateAMDTemp)
 	RegisterMem(updateAMDMem)
 	RegisterCPU(updateAMDUsage)
+	RegisterPower(updateAMDPower)
+	RegisterClock(updateAMDClock)
+	RegisterFan(updateAMDFan)
+	RegisterPCIe(updateAMDPCIeRate)
+	RegisterMetricsWriter(writeAMDMetrics)
 
 	amdLock = sync.Mutex{}
 	refresh := time.Second
@@ -113,11 +163,25 @@ var (
 	amdTemps  map[string]int
 	amdMems   map[string]MemoryInfo
 	amdCpus   map[string]int
+	amdPower  map[string]PowerInfo
+	amdClocks map[string]ClockInfo
+	amdFans   map[string]FanInfo
+	amdPCIe   map[string]PCIeInfo
 	amdErrors map[string]error
 )
 
 var amdLock sync.Mutex
 
+// amdPCIeSamples caches the previous pcie_bw reading per device so
+// updateAMD can turn the cumulative sysfs counters into a MB/s rate.
+var amdPCIeSamples = make(map[string]amdPCIeSample)
+
+type amdPCIeSample struct {
+	at      time.Time
+	rxBytes uint64
+	txBytes uint64
+}
+
 func updateAMD() {
 	gpus, err := discoverAMDGPUs()
 	if err != nil {
@@ -133,6 +197,10 @@ func updateAMD() {
 	temps := make(map[string]int)
 	mems := make(map[string]MemoryInfo)
 	cpus := make(map[string]int)
+	power := make(map[string]PowerInfo)
+	clocks := make(map[string]ClockInfo)
+	fans := make(map[string]FanInfo)
+	pcie := make(map[string]PCIeInfo)
 	errs := make(map[string]error)
 
 	for _, gpu := range gpus {
@@ -153,16 +221,78 @@ func updateAMD() {
 		} else {
 			errs[gpu.name] = err
 		}
+
+		if p, err := readAMDPower(gpu.devicePath); err == nil {
+			power[gpu.name] = p
+		} else {
+			errs[gpu.name] = err
+		}
+
+		if c, err := readAMDClocks(gpu.devicePath); err == nil {
+			clocks[gpu.name] = c
+		} else {
+			errs[gpu.name] = err
+		}
+
+		if f, err := readAMDFan(gpu.devicePath); err == nil {
+			fans[gpu.name] = f
+		} else {
+			errs[gpu.name] = err
+		}
+
+		if pc, err := readAMDPCIe(gpu.name, gpu.devicePath); err == nil {
+			pcie[gpu.name] = pc
+		} else {
+			errs[gpu.name] = err
+		}
 	}
 
 	amdLock.Lock()
 	amdTemps = temps
 	amdMems = mems
 	amdCpus = cpus
+	amdPower = power
+	amdClocks = clocks
+	amdFans = fans
+	amdPCIe = pcie
 	amdErrors = errs
 	amdLock.Unlock()
 }
 
+// writeAMDMetrics renders temp/util/mem/power/clock/fan/PCIe for every
+// cached AMD device as Prometheus gauges, reading state amdLock already
+// guards rather than touching hwmon/sysfs directly.
+func writeAMDMetrics(w io.Writer) {
+	amdLock.Lock()
+	defer amdLock.Unlock()
+
+	for device, v := range amdTemps {
+		writeMetric(w, "gotop_gpu_temperature_celsius", map[string]string{"vendor": "amd", "device": device}, float64(v))
+	}
+	for device, v := range amdCpus {
+		writeMetric(w, "gotop_gpu_utilization_percent", map[string]string{"vendor": "amd", "device": device}, float64(v))
+	}
+	for device, v := range amdMems {
+		writeMetric(w, "gotop_gpu_memory_used_bytes", map[string]string{"vendor": "amd", "device": device}, float64(v.Used))
+		writeMetric(w, "gotop_gpu_memory_total_bytes", map[string]string{"vendor": "amd", "device": device}, float64(v.Total))
+	}
+	for device, v := range amdPower {
+		writeMetric(w, "gotop_gpu_power_watts", map[string]string{"vendor": "amd", "device": device}, v.Watts)
+	}
+	for device, v := range amdClocks {
+		writeMetric(w, "gotop_gpu_core_clock_mhz", map[string]string{"vendor": "amd", "device": device}, float64(v.CoreMHz))
+		writeMetric(w, "gotop_gpu_memory_clock_mhz", map[string]string{"vendor": "amd", "device": device}, float64(v.MemMHz))
+	}
+	for device, v := range amdFans {
+		writeMetric(w, "gotop_gpu_fan_rpm", map[string]string{"vendor": "amd", "device": device}, float64(v.RPM))
+	}
+	for device, v := range amdPCIe {
+		writeMetric(w, "gotop_gpu_pcie_rx_mbps", map[string]string{"vendor": "amd", "device": device}, v.RxMBps)
+		writeMetric(w, "gotop_gpu_pcie_tx_mbps", map[string]string{"vendor": "amd", "device": device}, v.TxMBps)
+	}
+	writeScrapeErrors(w, "amd", amdErrors)
+}
+
 func discoverAMDGPUs() ([]amdGPU, error) {
 	entries, err := os.ReadDir("/sys/class/drm")
 	if err != nil {
@@ -350,6 +480,117 @@ func readAMDVram(devicePath string) (MemoryInfo, error) {
 	}, nil
 }
 
+func readAMDPower(devicePath string) (PowerInfo, error) {
+	hwmonPath, err := firstHwmonPath(devicePath)
+	if err != nil {
+		return PowerInfo{}, err
+	}
+	avgMicrowatts, err := readUint(filepath.Join(hwmonPath, "power1_average"))
+	if err != nil {
+		return PowerInfo{}, err
+	}
+	info := PowerInfo{Watts: float64(avgMicrowatts) / 1e6}
+	if capMicrowatts, err := readUint(filepath.Join(hwmonPath, "power1_cap")); err == nil {
+		info.LimitWatts = float64(capMicrowatts) / 1e6
+	}
+	return info, nil
+}
+
+// readAMDClocks parses the active (`*`-marked) state out of pp_dpm_sclk/pp_dpm_mclk,
+// e.g. "1: 1333Mhz *" for the currently selected DPM level.
+func readAMDClocks(devicePath string) (ClockInfo, error) {
+	core, err := readActiveDPMClock(filepath.Join(devicePath, "pp_dpm_sclk"))
+	if err != nil {
+		return ClockInfo{}, err
+	}
+	mem, err := readActiveDPMClock(filepath.Join(devicePath, "pp_dpm_mclk"))
+	if err != nil {
+		return ClockInfo{}, err
+	}
+	return ClockInfo{CoreMHz: core, MemMHz: mem}, nil
+}
+
+func readActiveDPMClock(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasSuffix(line, "*") {
+			continue
+		}
+		fields := strings.Fields(line)
+		for _, field := range fields {
+			field = strings.ToLower(field)
+			if strings.HasSuffix(field, "mhz") {
+				mhz := strings.TrimSuffix(field, "mhz")
+				val, err := strconv.Atoi(mhz)
+				if err != nil {
+					return 0, err
+				}
+				return val, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("AMD GPU error: no active DPM state found in %s", path)
+}
+
+func readAMDFan(devicePath string) (FanInfo, error) {
+	hwmonPath, err := firstHwmonPath(devicePath)
+	if err != nil {
+		return FanInfo{}, err
+	}
+	rpm, err := readInt(filepath.Join(hwmonPath, "fan1_input"))
+	if err != nil {
+		return FanInfo{}, err
+	}
+	info := FanInfo{RPM: rpm}
+	if pwm, err := readInt(filepath.Join(hwmonPath, "pwm1")); err == nil {
+		info.DutyPercent = (pwm * 100) / 255
+	}
+	return info, nil
+}
+
+// readAMDPCIe reads the cumulative pcie_bw counters (received bytes, sent
+// bytes, max payload size) and turns them into a MB/s rate using the
+// previous sample cached per device in amdPCIeSamples.
+func readAMDPCIe(name string, devicePath string) (PCIeInfo, error) {
+	data, err := os.ReadFile(filepath.Join(devicePath, "pcie_bw"))
+	if err != nil {
+		return PCIeInfo{}, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return PCIeInfo{}, errors.New("AMD GPU error: malformed pcie_bw")
+	}
+	rxBytes, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return PCIeInfo{}, err
+	}
+	txBytes, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return PCIeInfo{}, err
+	}
+
+	now := time.Now()
+	prev, ok := amdPCIeSamples[name]
+	amdPCIeSamples[name] = amdPCIeSample{at: now, rxBytes: rxBytes, txBytes: txBytes}
+	if !ok {
+		return PCIeInfo{}, nil
+	}
+
+	elapsed := now.Sub(prev.at).Seconds()
+	if elapsed <= 0 || rxBytes < prev.rxBytes || txBytes < prev.txBytes {
+		return PCIeInfo{}, nil
+	}
+	const mb = 1024 * 1024
+	return PCIeInfo{
+		RxMBps: float64(rxBytes-prev.rxBytes) / mb / elapsed,
+		TxMBps: float64(txBytes-prev.txBytes) / mb / elapsed,
+	}, nil
+}
+
 func firstHwmonPath(devicePath string) (string, error) {
 	hwmonRoot := filepath.Join(devicePath, "hwmon")
 	entries, err := os.ReadDir(hwmonRoot)
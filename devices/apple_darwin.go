@@ -13,6 +13,7 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 	"unsafe"
@@ -40,6 +41,7 @@ func startAppleGPU(vars map[string]string) error {
 
 	RegisterMem(updateAppleMem)
 	RegisterCPU(updateAppleUsage)
+	RegisterMetricsWriter(writeAppleMetrics)
 
 	refresh := time.Second
 	if v, ok := vars["apple-refresh"]; ok {
@@ -130,6 +132,23 @@ func updateAppleFromInfos(infos []appleGPUInfo) {
 	appleLock.Unlock()
 }
 
+// writeAppleMetrics renders the cached Apple GPU utilization and memory
+// gauges in Prometheus text format; Metal/IOKit expose no power, clock, or
+// PCIe data for this backend to surface.
+func writeAppleMetrics(w io.Writer) {
+	appleLock.Lock()
+	defer appleLock.Unlock()
+
+	for device, v := range appleCpus {
+		writeMetric(w, "gotop_gpu_utilization_percent", map[string]string{"vendor": "apple", "device": device}, float64(v))
+	}
+	for device, v := range appleMems {
+		writeMetric(w, "gotop_gpu_memory_used_bytes", map[string]string{"vendor": "apple", "device": device}, float64(v.Used))
+		writeMetric(w, "gotop_gpu_memory_total_bytes", map[string]string{"vendor": "apple", "device": device}, float64(v.Total))
+	}
+	writeScrapeErrors(w, "apple", appleErrors)
+}
+
 func readAppleGPUs() ([]appleGPUInfo, error) {
 	var cInfos *C.struct_apple_gpu_info
 	var cCount C.int
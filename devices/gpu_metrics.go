@@ -0,0 +1,131 @@
+package devices
+
+import "io"
+
+// PowerInfo describes a GPU's instantaneous power draw and the vendor-enforced cap.
+type PowerInfo struct {
+	Watts      float64
+	LimitWatts float64
+}
+
+// ClockInfo describes a GPU's core (SM) and memory clock speeds, in MHz.
+type ClockInfo struct {
+	CoreMHz int
+	MemMHz  int
+}
+
+// FanInfo describes a GPU fan's speed and duty cycle.
+type FanInfo struct {
+	RPM         int
+	DutyPercent int
+}
+
+// PCIeInfo describes a GPU's PCIe link throughput, in MB/s, sampled as a rate
+// between two refresh ticks.
+type PCIeInfo struct {
+	RxMBps float64
+	TxMBps float64
+}
+
+// Power is called on each refresh tick to collect per-device power draw. Implementations
+// return a map keyed by the same device label used by RegisterTemp/RegisterMem/RegisterCPU.
+type Power func(map[string]PowerInfo) map[string]error
+
+// Clock is called on each refresh tick to collect per-device clock speeds.
+type Clock func(map[string]ClockInfo) map[string]error
+
+// Fan is called on each refresh tick to collect per-device fan speed/duty.
+type Fan func(map[string]FanInfo) map[string]error
+
+// PCIe is called on each refresh tick to collect per-device PCIe throughput.
+type PCIe func(map[string]PCIeInfo) map[string]error
+
+// CodecInfo describes a GPU's video encoder/decoder engine utilization.
+type CodecInfo struct {
+	EncPercent int
+	DecPercent int
+}
+
+// Codec is called on each refresh tick to collect per-device encoder/decoder utilization.
+type Codec func(map[string]CodecInfo) map[string]error
+
+// ECCInfo describes a GPU's accumulated ECC memory error counts.
+type ECCInfo struct {
+	SingleBit uint64
+	DoubleBit uint64
+}
+
+// ECC is called on each refresh tick to collect per-device ECC error counts.
+type ECC func(map[string]ECCInfo) map[string]error
+
+// GPUProcessInfo describes one process's usage of a single GPU.
+type GPUProcessInfo struct {
+	PID        int
+	Comm       string
+	MemBytes   uint64
+	SMPercent  int
+	EncPercent int
+	DecPercent int
+}
+
+// GPUProcess is called on each refresh tick to collect, per device label, the
+// processes currently using that GPU.
+type GPUProcess func(map[string][]GPUProcessInfo) map[string]error
+
+// MetricsWriter renders a subsystem's already-cached metrics (no fresh
+// hardware probe) as Prometheus/OpenMetrics text lines, so ServeMetrics can
+// be scraped concurrently with the TUI's own refresh ticker without
+// doubling collection work.
+type MetricsWriter func(w io.Writer)
+
+var (
+	powerFuncs         []Power
+	clockFuncs         []Clock
+	fanFuncs           []Fan
+	pcieFuncs          []PCIe
+	codecFuncs         []Codec
+	eccFuncs           []ECC
+	gpuProcessFuncs    []GPUProcess
+	metricsWriterFuncs []MetricsWriter
+)
+
+// RegisterPower registers a callback that reports per-device power draw and power limit.
+func RegisterPower(f Power) {
+	powerFuncs = append(powerFuncs, f)
+}
+
+// RegisterClock registers a callback that reports per-device core/memory clock speeds.
+func RegisterClock(f Clock) {
+	clockFuncs = append(clockFuncs, f)
+}
+
+// RegisterFan registers a callback that reports per-device fan speed/duty.
+func RegisterFan(f Fan) {
+	fanFuncs = append(fanFuncs, f)
+}
+
+// RegisterPCIe registers a callback that reports per-device PCIe link throughput.
+func RegisterPCIe(f PCIe) {
+	pcieFuncs = append(pcieFuncs, f)
+}
+
+// RegisterCodec registers a callback that reports per-device encoder/decoder utilization.
+func RegisterCodec(f Codec) {
+	codecFuncs = append(codecFuncs, f)
+}
+
+// RegisterECC registers a callback that reports per-device ECC error counts.
+func RegisterECC(f ECC) {
+	eccFuncs = append(eccFuncs, f)
+}
+
+// RegisterGPUProcess registers a callback that reports per-device GPU process lists.
+func RegisterGPUProcess(f GPUProcess) {
+	gpuProcessFuncs = append(gpuProcessFuncs, f)
+}
+
+// RegisterMetricsWriter registers a callback that renders one subsystem's
+// cached metrics for ServeMetrics.
+func RegisterMetricsWriter(f MetricsWriter) {
+	metricsWriterFuncs = append(metricsWriterFuncs, f)
+}
@@ -0,0 +1,278 @@
+//go:build linux
+// +build linux
+
+package devices
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	RegisterStartup(startGPUProcessAccounting)
+}
+
+func startGPUProcessAccounting(vars map[string]string) error {
+	if vars["gpu-process"] == "false" {
+		return nil
+	}
+
+	// Like startAMD/startNVIDIA/startAppleGPU, no-op rather than walking
+	// every process's fdinfo directory on hosts with no GPU to account for.
+	// Only AMD GPUs are checked here, so on a host without one, DRM clients
+	// of other vendors' GPUs are not accounted for either.
+	gpus, err := discoverAMDGPUs()
+	if err != nil || len(gpus) == 0 {
+		return nil
+	}
+
+	gpuProcsErrors = make(map[string]error)
+	gpuProcs = make(map[string][]GPUProcessInfo)
+
+	RegisterGPUProcess(updateGPUProcesses)
+
+	refresh := time.Second
+	if v, ok := vars["gpu-process-refresh"]; ok {
+		var err error
+		if refresh, err = time.ParseDuration(v); err != nil {
+			return err
+		}
+	}
+
+	updateGPUProcessAccounting()
+	go func() {
+		timer := time.Tick(refresh)
+		for range timer {
+			updateGPUProcessAccounting()
+		}
+	}()
+	return nil
+}
+
+var (
+	gpuProcs       map[string][]GPUProcessInfo
+	gpuProcsErrors map[string]error
+)
+
+var gpuProcsLock sync.Mutex
+
+func updateGPUProcesses(procs map[string][]GPUProcessInfo) map[string]error {
+	gpuProcsLock.Lock()
+	defer gpuProcsLock.Unlock()
+	for k, v := range gpuProcs {
+		procs[k] = v
+	}
+	return gpuProcsErrors
+}
+
+// drmEngineSample caches the previous cumulative ns counter for one
+// pid/fd/engine triple so cycle counts can be diffed into a percentage.
+type drmEngineSample struct {
+	at time.Time
+	ns uint64
+}
+
+// drmEngineSamples is rebuilt from scratch every tick (see
+// updateGPUProcessAccounting) rather than mutated in place, so pid/fd keys
+// for processes that have exited are dropped instead of accumulating for
+// the life of the gotop process.
+var drmEngineSamples = make(map[string]drmEngineSample)
+
+func updateGPUProcessAccounting() {
+	labels, err := drmPdevLabels()
+	if err != nil {
+		gpuProcsLock.Lock()
+		if gpuProcsErrors == nil {
+			gpuProcsErrors = make(map[string]error)
+		}
+		gpuProcsErrors["gpu-process"] = err
+		gpuProcsLock.Unlock()
+		return
+	}
+
+	now := time.Now()
+	byLabel := make(map[string][]GPUProcessInfo)
+	nextSamples := make(map[string]drmEngineSample, len(drmEngineSamples))
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		gpuProcsLock.Lock()
+		if gpuProcsErrors == nil {
+			gpuProcsErrors = make(map[string]error)
+		}
+		gpuProcsErrors["gpu-process"] = err
+		gpuProcsLock.Unlock()
+		return
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		comm := readComm(pid)
+		fdinfoDir := filepath.Join("/proc", entry.Name(), "fdinfo")
+		fds, err := os.ReadDir(fdinfoDir)
+		if err != nil {
+			continue
+		}
+		// A process can hold multiple DRM fds for the same card (one per
+		// context); per (pid, pdev), engine percentages are summed across
+		// fds and the largest VRAM figure is kept.
+		byPdev := make(map[string]GPUProcessInfo)
+		for _, fd := range fds {
+			stats, pdev, ok := parseDRMFdinfo(filepath.Join(fdinfoDir, fd.Name()))
+			if !ok {
+				continue
+			}
+			info := byPdev[pdev]
+			info.PID = pid
+			info.Comm = comm
+			if stats.memVRAM > info.MemBytes {
+				info.MemBytes = stats.memVRAM
+			}
+			key := entry.Name() + ":" + fd.Name()
+			info.SMPercent += drmEnginePercent(nextSamples, key+":gfx", stats.gfxNS, now) + drmEnginePercent(nextSamples, key+":compute", stats.computeNS, now)
+			info.EncPercent += drmEnginePercent(nextSamples, key+":enc", stats.encNS, now)
+			info.DecPercent += drmEnginePercent(nextSamples, key+":dec", stats.decNS, now)
+			byPdev[pdev] = info
+		}
+		for pdev, info := range byPdev {
+			label, ok := labels[pdev]
+			if !ok {
+				label = pdev
+			}
+			byLabel[label] = append(byLabel[label], info)
+		}
+	}
+
+	gpuProcsLock.Lock()
+	gpuProcs = byLabel
+	gpuProcsErrors = make(map[string]error)
+	gpuProcsLock.Unlock()
+
+	drmEngineSamples = nextSamples
+}
+
+// drmEnginePercent diffs ns against the previous tick's sample (read from the
+// package-level drmEngineSamples) and records the new sample in next, the
+// map that becomes drmEngineSamples for the following tick. Keys for fds
+// that no longer appear are naturally dropped since next only ever holds
+// entries seen during the current pass.
+func drmEnginePercent(next map[string]drmEngineSample, key string, ns uint64, now time.Time) int {
+	prev, ok := drmEngineSamples[key]
+	next[key] = drmEngineSample{at: now, ns: ns}
+	if !ok || ns < prev.ns {
+		return 0
+	}
+	elapsedNS := now.Sub(prev.at).Nanoseconds()
+	if elapsedNS <= 0 {
+		return 0
+	}
+	return int((100 * (ns - prev.ns)) / uint64(elapsedNS))
+}
+
+type drmFdStats struct {
+	memVRAM   uint64
+	gfxNS     uint64
+	computeNS uint64
+	encNS     uint64
+	decNS     uint64
+}
+
+// parseDRMFdinfo reads the standardized `drm-*` keys from a single
+// /proc/<pid>/fdinfo/<fd> entry. Returns ok=false for non-DRM fds.
+func parseDRMFdinfo(path string) (drmFdStats, string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return drmFdStats{}, "", false
+	}
+	defer f.Close()
+
+	var stats drmFdStats
+	pdev := ""
+	isDRM := false
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		val = strings.TrimSpace(val)
+		switch {
+		case key == "drm-pdev":
+			pdev = val
+			isDRM = true
+		case key == "drm-client-id":
+			isDRM = true
+		case key == "drm-memory-vram":
+			stats.memVRAM = parseKiBField(val)
+		case key == "drm-engine-gfx":
+			stats.gfxNS = parseNSField(val)
+		case key == "drm-engine-compute":
+			stats.computeNS = parseNSField(val)
+		case key == "drm-engine-enc":
+			stats.encNS = parseNSField(val)
+		case key == "drm-engine-dec":
+			stats.decNS = parseNSField(val)
+		}
+	}
+	if !isDRM || pdev == "" {
+		return drmFdStats{}, "", false
+	}
+	return stats, pdev, true
+}
+
+// parseNSField parses values like "123456789 ns".
+func parseNSField(val string) uint64 {
+	fields := strings.Fields(val)
+	if len(fields) == 0 {
+		return 0
+	}
+	n, _ := strconv.ParseUint(fields[0], 10, 64)
+	return n
+}
+
+// parseKiBField parses values like "131072 KiB" into bytes.
+func parseKiBField(val string) uint64 {
+	fields := strings.Fields(val)
+	if len(fields) == 0 {
+		return 0
+	}
+	n, _ := strconv.ParseUint(fields[0], 10, 64)
+	return n * 1024
+}
+
+func readComm(pid int) string {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// drmPdevLabels maps a device's PCI_SLOT_NAME (as reported via drm-pdev,
+// e.g. "0000:03:00.0") to the device label already used for its
+// temperature/memory/usage metrics, so GPU process rows line up with the
+// rest of the UI.
+func drmPdevLabels() (map[string]string, error) {
+	gpus, err := discoverAMDGPUs()
+	if err != nil {
+		return map[string]string{}, nil
+	}
+	labels := make(map[string]string, len(gpus))
+	for _, gpu := range gpus {
+		if slot := pciSlotName(gpu.devicePath); slot != "" {
+			labels[slot] = gpu.name
+		}
+	}
+	return labels, nil
+}
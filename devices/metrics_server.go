@@ -0,0 +1,61 @@
+package devices
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ServeMetrics starts an HTTP server exposing every registered subsystem's
+// cached metrics in Prometheus text format on addr. Handlers only read the
+// maps already maintained by each subsystem's own refresh ticker (see
+// RegisterMetricsWriter) — scraping never triggers a fresh hardware probe,
+// so it's safe to run this concurrently with the TUI.
+//
+// TODO(cmd/gotop): this tree has no cmd/main package yet, so there's no
+// --listen flag to wire this up to; callers embedding the devices package
+// call ServeMetrics(addr) directly until one exists. Whoever wires up
+// cmd/gotop's flag parsing needs to call this.
+func ServeMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", handleMetrics)
+	return http.ListenAndServe(addr, mux)
+}
+
+func handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, f := range metricsWriterFuncs {
+		f(w)
+	}
+}
+
+// writeMetric writes one Prometheus gauge sample, e.g.
+// writeMetric(w, "gotop_gpu_temperature_celsius", map[string]string{"vendor": "amd", "device": "MI250.03:00"}, 61)
+func writeMetric(w io.Writer, name string, labels map[string]string, value float64) {
+	fmt.Fprintf(w, "%s%s %v\n", name, formatLabels(labels), value)
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%q", k, labels[k])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// writeScrapeErrors emits gotop_scrape_error{subsystem="..."} gauges so a
+// vendor's collection health is visible even when every individual device
+// metric is absent.
+func writeScrapeErrors(w io.Writer, subsystem string, errs map[string]error) {
+	writeMetric(w, "gotop_scrape_error", map[string]string{"subsystem": subsystem}, float64(len(errs)))
+}
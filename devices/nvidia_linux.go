@@ -0,0 +1,557 @@
+//go:build linux && cgo
+// +build linux,cgo
+
+package devices
+
+/*
+#cgo LDFLAGS: -ldl
+#include <stdlib.h>
+#include "nvml_linux.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+func init() {
+	RegisterStartup(startNVIDIA)
+	nvidiaTopologyDevices = readNVIDIATopologyDevices
+	nvidiaTopologyLink = readNVIDIATopologyLink
+}
+
+func startNVIDIA(vars map[string]string) error {
+	enabled := vars["nvidia"] == "true"
+	disabled := vars["nvidia"] == "false"
+	if disabled {
+		return nil
+	}
+
+	if err := nvmlOpen(); err != nil {
+		if enabled {
+			return err
+		}
+		return nil
+	}
+
+	infos, err := readNVIDIAGPUs()
+	if err != nil {
+		if enabled {
+			return err
+		}
+		return nil
+	}
+	if len(infos) == 0 {
+		if enabled {
+			return errors.New("NVIDIA GPU error: no NVIDIA GPUs found")
+		}
+		return nil
+	}
+
+	nvidiaErrors = make(map[string]error)
+	nvidiaTemps = make(map[string]int)
+	nvidiaMems = make(map[string]MemoryInfo)
+	nvidiaCpus = make(map[string]int)
+	nvidiaPower = make(map[string]PowerInfo)
+	nvidiaClocks = make(map[string]ClockInfo)
+	nvidiaPCIe = make(map[string]PCIeInfo)
+	nvidiaCodec = make(map[string]CodecInfo)
+	nvidiaECC = make(map[string]ECCInfo)
+
+	nvidiaGPUProcs = make(map[string][]GPUProcessInfo)
+
+	RegisterTemp(updateNVIDIATemp)
+	RegisterMem(updateNVIDIAMem)
+	RegisterCPU(updateNVIDIAUsage)
+	RegisterPower(updateNVIDIAPower)
+	RegisterClock(updateNVIDIAClock)
+	RegisterPCIe(updateNVIDIAPCIe)
+	RegisterCodec(updateNVIDIACodec)
+	RegisterECC(updateNVIDIAECC)
+	RegisterGPUProcess(updateNVIDIAProcesses)
+	RegisterMetricsWriter(writeNVIDIAMetrics)
+
+	refresh := time.Second
+	if v, ok := vars["nvidia-refresh"]; ok {
+		if refresh, err = time.ParseDuration(v); err != nil {
+			return err
+		}
+	}
+
+	labels := updateNVIDIA()
+	updateNVIDIAProcessAccounting(labels)
+	go func() {
+		timer := time.Tick(refresh)
+		for range timer {
+			labels := updateNVIDIA()
+			updateNVIDIAProcessAccounting(labels)
+		}
+	}()
+	return nil
+}
+
+var (
+	nvidiaTemps    map[string]int
+	nvidiaMems     map[string]MemoryInfo
+	nvidiaCpus     map[string]int
+	nvidiaPower    map[string]PowerInfo
+	nvidiaClocks   map[string]ClockInfo
+	nvidiaPCIe     map[string]PCIeInfo
+	nvidiaCodec    map[string]CodecInfo
+	nvidiaECC      map[string]ECCInfo
+	nvidiaGPUProcs map[string][]GPUProcessInfo
+	nvidiaErrors   map[string]error
+)
+
+var (
+	nvidiaLock   sync.Mutex
+	nvmlOpenOnce sync.Once
+	nvmlOpenErr  error
+)
+
+// nvmlOpen dlopens libnvidia-ml.so.1 exactly once; subsequent calls reuse the
+// same handle, matching the cached-handle behavior requested for the
+// nvidia-refresh cadence.
+func nvmlOpen() error {
+	nvmlOpenOnce.Do(func() {
+		var cErr *C.char
+		if rc := C.nvml_open(&cErr); rc != 0 {
+			defer C.nvml_free_error(cErr)
+			if cErr != nil {
+				nvmlOpenErr = errors.New(C.GoString(cErr))
+			} else {
+				nvmlOpenErr = errors.New("NVIDIA GPU error: nvml_open failed")
+			}
+		}
+	})
+	return nvmlOpenErr
+}
+
+func updateNVIDIATemp(temps map[string]int) map[string]error {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+	for k, v := range nvidiaTemps {
+		temps[k] = v
+	}
+	return nvidiaErrors
+}
+
+func updateNVIDIAMem(mems map[string]MemoryInfo) map[string]error {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+	for k, v := range nvidiaMems {
+		mems[k] = v
+	}
+	return nvidiaErrors
+}
+
+func updateNVIDIAUsage(cpus map[string]int, _ bool) map[string]error {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+	for k, v := range nvidiaCpus {
+		cpus[k] = v
+	}
+	return nvidiaErrors
+}
+
+func updateNVIDIAPower(power map[string]PowerInfo) map[string]error {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+	for k, v := range nvidiaPower {
+		power[k] = v
+	}
+	return nvidiaErrors
+}
+
+func updateNVIDIAClock(clocks map[string]ClockInfo) map[string]error {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+	for k, v := range nvidiaClocks {
+		clocks[k] = v
+	}
+	return nvidiaErrors
+}
+
+func updateNVIDIAPCIe(pcie map[string]PCIeInfo) map[string]error {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+	for k, v := range nvidiaPCIe {
+		pcie[k] = v
+	}
+	return nvidiaErrors
+}
+
+func updateNVIDIACodec(codec map[string]CodecInfo) map[string]error {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+	for k, v := range nvidiaCodec {
+		codec[k] = v
+	}
+	return nvidiaErrors
+}
+
+func updateNVIDIAECC(ecc map[string]ECCInfo) map[string]error {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+	for k, v := range nvidiaECC {
+		ecc[k] = v
+	}
+	return nvidiaErrors
+}
+
+func updateNVIDIAProcesses(procs map[string][]GPUProcessInfo) map[string]error {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+	for k, v := range nvidiaGPUProcs {
+		procs[k] = v
+	}
+	return nvidiaErrors
+}
+
+func updateNVIDIAProcessAccounting(labels []string) {
+	byDevice, err := readNVIDIAProcesses(labels)
+	if err != nil {
+		nvidiaLock.Lock()
+		if nvidiaErrors == nil {
+			nvidiaErrors = make(map[string]error)
+		}
+		nvidiaErrors["nvidia-process"] = err
+		nvidiaLock.Unlock()
+		return
+	}
+	nvidiaLock.Lock()
+	nvidiaGPUProcs = byDevice
+	nvidiaLock.Unlock()
+}
+
+// readNVIDIAProcesses calls into NVML's per-process accounting APIs and
+// labels each device's process list using labels, the same
+// nvmlDeviceGetHandleByIndex_v2-ordered device labels updateNVIDIA() already
+// computed this tick, so this doesn't have to re-walk every device's full
+// NVML metric set a second time just to resolve names.
+func readNVIDIAProcesses(labels []string) (map[string][]GPUProcessInfo, error) {
+	if err := nvmlOpen(); err != nil {
+		return nil, err
+	}
+
+	var cDevs *C.struct_nvml_device_processes
+	var cCount C.int
+	var cErr *C.char
+
+	if rc := C.nvml_get_process_infos(&cDevs, &cCount, &cErr); rc != 0 {
+		defer C.nvml_free_error(cErr)
+		if cErr != nil {
+			return nil, errors.New(C.GoString(cErr))
+		}
+		return nil, errors.New("NVIDIA GPU error: nvml_get_process_infos failed")
+	}
+	defer C.nvml_free_process_infos(cDevs)
+
+	count := int(cCount)
+	if count == 0 {
+		return map[string][]GPUProcessInfo{}, nil
+	}
+
+	// nvml_get_process_infos walks devices in the same nvmlDeviceGetHandleByIndex_v2
+	// order as readNVIDIAGPUs, so device i here is device i there.
+	devSlice := (*[1 << 16]C.struct_nvml_device_processes)(unsafe.Pointer(cDevs))[:count:count]
+	byDevice := make(map[string][]GPUProcessInfo, count)
+	for i, dev := range devSlice {
+		label := formatNVIDIALabel("NVIDIA", simplifyPCISlot(C.GoString(&dev.pci_slot[0])), i)
+		if i < len(labels) {
+			label = labels[i]
+		}
+
+		n := int(dev.count)
+		procSlice := (*[1 << 16]C.struct_nvml_process_info)(unsafe.Pointer(&dev.procs[0]))[:n:n]
+		rows := make([]GPUProcessInfo, 0, n)
+		for _, p := range procSlice {
+			pid := int(p.pid)
+			rows = append(rows, GPUProcessInfo{
+				PID:        pid,
+				Comm:       readComm(pid),
+				MemBytes:   uint64(p.mem_bytes),
+				SMPercent:  int(p.sm_pct),
+				EncPercent: int(p.enc_pct),
+				DecPercent: int(p.dec_pct),
+			})
+		}
+		byDevice[label] = rows
+	}
+	return byDevice, nil
+}
+
+// updateNVIDIA refreshes every per-device metric cache and returns the
+// device labels (in nvmlDeviceGetHandleByIndex_v2 order) so callers that
+// need the same device set this tick, like updateNVIDIAProcessAccounting,
+// don't have to repeat the full NVML device sweep just to get them.
+func updateNVIDIA() []string {
+	infos, err := readNVIDIAGPUs()
+	if err != nil {
+		nvidiaLock.Lock()
+		if nvidiaErrors == nil {
+			nvidiaErrors = make(map[string]error)
+		}
+		nvidiaErrors["nvidia"] = err
+		nvidiaLock.Unlock()
+		return nil
+	}
+
+	labels := make([]string, len(infos))
+	temps := make(map[string]int, len(infos))
+	mems := make(map[string]MemoryInfo, len(infos))
+	cpus := make(map[string]int, len(infos))
+	power := make(map[string]PowerInfo, len(infos))
+	clocks := make(map[string]ClockInfo, len(infos))
+	pcie := make(map[string]PCIeInfo, len(infos))
+	codec := make(map[string]CodecInfo, len(infos))
+	ecc := make(map[string]ECCInfo, len(infos))
+	errs := make(map[string]error)
+
+	for i, info := range infos {
+		labels[i] = info.label
+		temps[info.label] = info.tempC
+		cpus[info.label] = info.gpuUtilPct
+		mems[info.label] = MemoryInfo{
+			Total:       info.memTotal,
+			Used:        info.memUsed,
+			UsedPercent: memPercent(info.memUsed, info.memTotal),
+		}
+		power[info.label] = PowerInfo{
+			Watts:      info.powerWatts,
+			LimitWatts: info.powerLimitWatts,
+		}
+		clocks[info.label] = ClockInfo{
+			CoreMHz: info.smClockMHz,
+			MemMHz:  info.memClockMHz,
+		}
+		// nvmlDeviceGetPcieThroughput reports KB/s; convert to MB/s to match
+		// the AMD PCIe reader's units.
+		pcie[info.label] = PCIeInfo{
+			RxMBps: float64(info.pcieRxKBps) / 1024,
+			TxMBps: float64(info.pcieTxKBps) / 1024,
+		}
+		codec[info.label] = CodecInfo{
+			EncPercent: info.encUtilPct,
+			DecPercent: info.decUtilPct,
+		}
+		ecc[info.label] = ECCInfo{
+			SingleBit: info.eccSBE,
+			DoubleBit: info.eccDBE,
+		}
+	}
+
+	nvidiaLock.Lock()
+	nvidiaTemps = temps
+	nvidiaMems = mems
+	nvidiaCpus = cpus
+	nvidiaPower = power
+	nvidiaClocks = clocks
+	nvidiaPCIe = pcie
+	nvidiaCodec = codec
+	nvidiaECC = ecc
+	nvidiaErrors = errs
+	nvidiaLock.Unlock()
+	return labels
+}
+
+// writeNVIDIAMetrics renders the full NVML-derived gauge set — temp, util,
+// mem, power, clock, PCIe throughput, encoder/decoder utilization, and ECC
+// error counts — for every cached device as Prometheus text.
+func writeNVIDIAMetrics(w io.Writer) {
+	nvidiaLock.Lock()
+	defer nvidiaLock.Unlock()
+
+	for device, v := range nvidiaTemps {
+		writeMetric(w, "gotop_gpu_temperature_celsius", map[string]string{"vendor": "nvidia", "device": device}, float64(v))
+	}
+	for device, v := range nvidiaCpus {
+		writeMetric(w, "gotop_gpu_utilization_percent", map[string]string{"vendor": "nvidia", "device": device}, float64(v))
+	}
+	for device, v := range nvidiaMems {
+		writeMetric(w, "gotop_gpu_memory_used_bytes", map[string]string{"vendor": "nvidia", "device": device}, float64(v.Used))
+		writeMetric(w, "gotop_gpu_memory_total_bytes", map[string]string{"vendor": "nvidia", "device": device}, float64(v.Total))
+	}
+	for device, v := range nvidiaPower {
+		writeMetric(w, "gotop_gpu_power_watts", map[string]string{"vendor": "nvidia", "device": device}, v.Watts)
+	}
+	for device, v := range nvidiaClocks {
+		writeMetric(w, "gotop_gpu_core_clock_mhz", map[string]string{"vendor": "nvidia", "device": device}, float64(v.CoreMHz))
+		writeMetric(w, "gotop_gpu_memory_clock_mhz", map[string]string{"vendor": "nvidia", "device": device}, float64(v.MemMHz))
+	}
+	for device, v := range nvidiaPCIe {
+		writeMetric(w, "gotop_gpu_pcie_rx_mbps", map[string]string{"vendor": "nvidia", "device": device}, v.RxMBps)
+		writeMetric(w, "gotop_gpu_pcie_tx_mbps", map[string]string{"vendor": "nvidia", "device": device}, v.TxMBps)
+	}
+	for device, v := range nvidiaCodec {
+		writeMetric(w, "gotop_gpu_encoder_utilization_percent", map[string]string{"vendor": "nvidia", "device": device}, float64(v.EncPercent))
+		writeMetric(w, "gotop_gpu_decoder_utilization_percent", map[string]string{"vendor": "nvidia", "device": device}, float64(v.DecPercent))
+	}
+	for device, v := range nvidiaECC {
+		writeMetric(w, "gotop_gpu_ecc_single_bit_errors_total", map[string]string{"vendor": "nvidia", "device": device}, float64(v.SingleBit))
+		writeMetric(w, "gotop_gpu_ecc_double_bit_errors_total", map[string]string{"vendor": "nvidia", "device": device}, float64(v.DoubleBit))
+	}
+	writeScrapeErrors(w, "nvidia", nvidiaErrors)
+}
+
+func memPercent(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return (float64(used) / float64(total)) * 100.0
+}
+
+type nvidiaGPU struct {
+	label           string
+	rawSlot         string
+	gpuUtilPct      int
+	memUtilPct      int
+	memTotal        uint64
+	memUsed         uint64
+	tempC           int
+	powerWatts      float64
+	powerLimitWatts float64
+	smClockMHz      int
+	memClockMHz     int
+	encUtilPct      int
+	decUtilPct      int
+	pcieRxKBps      uint64
+	pcieTxKBps      uint64
+	eccSBE          uint64
+	eccDBE          uint64
+}
+
+func readNVIDIAGPUs() ([]nvidiaGPU, error) {
+	if err := nvmlOpen(); err != nil {
+		return nil, err
+	}
+
+	var cInfos *C.struct_nvml_device_info
+	var cCount C.int
+	var cErr *C.char
+
+	if rc := C.nvml_get_device_infos(&cInfos, &cCount, &cErr); rc != 0 {
+		defer C.nvml_free_error(cErr)
+		if cErr != nil {
+			return nil, errors.New(C.GoString(cErr))
+		}
+		return nil, errors.New("NVIDIA GPU error: nvml_get_device_infos failed")
+	}
+	defer C.nvml_free_infos(cInfos)
+
+	count := int(cCount)
+	if count == 0 {
+		return nil, nil
+	}
+
+	cSlice := (*[1 << 20]C.struct_nvml_device_info)(unsafe.Pointer(cInfos))[:count:count]
+	gpus := make([]nvidiaGPU, 0, count)
+	for i, di := range cSlice {
+		name := strings.TrimSpace(C.GoString(&di.name[0]))
+		rawSlot := C.GoString(&di.pci_slot[0])
+		slot := simplifyPCISlot(rawSlot)
+		label := formatNVIDIALabel(name, slot, i)
+		gpus = append(gpus, nvidiaGPU{
+			label:           label,
+			rawSlot:         rawSlot,
+			gpuUtilPct:      int(di.gpu_util_pct),
+			memUtilPct:      int(di.mem_util_pct),
+			memTotal:        uint64(di.mem_total_bytes),
+			memUsed:         uint64(di.mem_used_bytes),
+			tempC:           int(di.temp_c),
+			powerWatts:      float64(di.power_watts),
+			powerLimitWatts: float64(di.power_limit_watts),
+			smClockMHz:      int(di.sm_clock_mhz),
+			memClockMHz:     int(di.mem_clock_mhz),
+			encUtilPct:      int(di.enc_util_pct),
+			decUtilPct:      int(di.dec_util_pct),
+			pcieRxKBps:      uint64(di.pcie_rx_kbps),
+			pcieTxKBps:      uint64(di.pcie_tx_kbps),
+			eccSBE:          uint64(di.ecc_sbe),
+			eccDBE:          uint64(di.ecc_dbe),
+		})
+	}
+	return gpus, nil
+}
+
+// readNVIDIATopologyDevices feeds Topology() the label/slot pairs for every
+// visible NVIDIA GPU and caches each slot's NVML device index so
+// readNVIDIATopologyLink can look handles back up by slot.
+func readNVIDIATopologyDevices() ([]topologyDevice, error) {
+	infos, err := readNVIDIAGPUs()
+	if err != nil {
+		return nil, err
+	}
+
+	nvidiaLock.Lock()
+	if nvidiaSlotIndex == nil {
+		nvidiaSlotIndex = make(map[string]int, len(infos))
+	}
+	devs := make([]topologyDevice, 0, len(infos))
+	for i, info := range infos {
+		nvidiaSlotIndex[info.rawSlot] = i
+		devs = append(devs, topologyDevice{label: info.label, slot: info.rawSlot})
+	}
+	nvidiaLock.Unlock()
+	return devs, nil
+}
+
+var nvidiaSlotIndex map[string]int
+
+func readNVIDIATopologyLink(slotA, slotB string) LinkType {
+	nvidiaLock.Lock()
+	indexA, okA := nvidiaSlotIndex[slotA]
+	indexB, okB := nvidiaSlotIndex[slotB]
+	nvidiaLock.Unlock()
+	if !okA || !okB {
+		return LinkUnknown
+	}
+
+	var cErr *C.char
+	level := C.nvml_topology_level(C.int(indexA), C.int(indexB), &cErr)
+	if level < 0 {
+		if cErr != nil {
+			C.nvml_free_error(cErr)
+		}
+		return LinkUnknown
+	}
+	return nvmlTopologyLevelToLinkType(int(level))
+}
+
+// nvmlTopologyLevelToLinkType maps nvmlGpuTopologyLevel_t onto our LinkType
+// taxonomy. NVML orders levels from closest to farthest:
+// INTERNAL/SINGLE/MULTIPLE(switch), HOSTBRIDGE, NODE (same CPU), SYSTEM (cross CPU).
+func nvmlTopologyLevelToLinkType(level int) LinkType {
+	switch level {
+	case 0: // NVML_TOPOLOGY_INTERNAL
+		return LinkSameBoard
+	case 10: // NVML_TOPOLOGY_SINGLE
+		return LinkSingleSwitch
+	case 20: // NVML_TOPOLOGY_MULTIPLE
+		return LinkMultiSwitch
+	case 30: // NVML_TOPOLOGY_HOSTBRIDGE
+		return LinkHostBridge
+	case 40: // NVML_TOPOLOGY_NODE
+		return LinkSameCPU
+	case 50: // NVML_TOPOLOGY_SYSTEM
+		return LinkCrossCPU
+	default:
+		return LinkUnknown
+	}
+}
+
+func formatNVIDIALabel(name string, slot string, index int) string {
+	if name == "" {
+		name = "NVIDIA"
+	}
+	if slot != "" {
+		return fmt.Sprintf("%s.%s", name, slot)
+	}
+	return fmt.Sprintf("%s.%d", name, index)
+}
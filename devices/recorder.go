@@ -0,0 +1,188 @@
+package devices
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// recorderCSVMetaHeader/recorderCSVColumns mirror the MangoHud benchmark-log
+// schema, so files produced by StartRecorder drop straight into
+// MangoHud-style comparison tools. gotop has no concept of a rendered
+// frame, so fps/frametime (and any host CPU metric this tree doesn't
+// collect, such as cpu_load/cpu_temp) are left blank rather than faked.
+const (
+	recorderCSVMetaHeader = "os,cpu,gpu,ram,kernel"
+	recorderCSVColumns    = "fps,frametime,cpu_load,gpu_load,cpu_temp,gpu_temp,gpu_core_clock,gpu_mem_clock,gpu_vram_used,gpu_power,ram_used,swap_used"
+)
+
+// RecorderSample is one tick of every GPU metric gotop has a registered
+// collector for. The CSV writer reduces this to a single representative
+// device per column (first one seen, and the fixed MangoHud column list has
+// no fan/PCIe slot to put them in); the JSONL writer keeps full detail,
+// fan and PCIe included.
+type RecorderSample struct {
+	Time     time.Time             `json:"time"`
+	GPULoad  map[string]int        `json:"gpu_load"`
+	GPUTemp  map[string]int        `json:"gpu_temp"`
+	GPUMem   map[string]MemoryInfo `json:"gpu_mem"`
+	GPUPower map[string]PowerInfo  `json:"gpu_power"`
+	GPUClock map[string]ClockInfo  `json:"gpu_clock"`
+	GPUFan   map[string]FanInfo    `json:"gpu_fan"`
+	GPUPCIe  map[string]PCIeInfo   `json:"gpu_pcie"`
+}
+
+// StartRecorder snapshots every registered device metric on each tick and
+// appends a row to path, in the requested format ("csv" or "jsonl"). It
+// returns once the file is open and the background writer goroutine is
+// running; recording continues for the life of the process.
+//
+// TODO(cmd/gotop): this tree has no cmd/main package yet, so there are no
+// --record, --record-format, or --record-interval flags to parse; callers
+// embedding the devices package call StartRecorder directly until one
+// exists. Whoever wires up cmd/gotop's flag parsing needs to call this.
+func StartRecorder(path string, interval time.Duration, format string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("devices: could not open recorder output %q: %w", path, err)
+	}
+
+	switch format {
+	case "csv":
+		return startCSVRecorder(f, interval)
+	case "jsonl":
+		return startJSONLRecorder(f, interval)
+	default:
+		f.Close()
+		return fmt.Errorf("devices: unknown recorder format %q (want \"csv\" or \"jsonl\")", format)
+	}
+}
+
+func startCSVRecorder(f *os.File, interval time.Duration) error {
+	w := bufio.NewWriter(f)
+
+	if info, err := f.Stat(); err == nil && info.Size() == 0 {
+		sample := snapshotRecorderSample()
+		fmt.Fprintln(w, recorderCSVMetaHeader)
+		fmt.Fprintln(w, recorderCSVMetaRow(sample))
+		fmt.Fprintln(w, recorderCSVColumns)
+		if err := w.Flush(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer f.Close()
+		ticker := time.Tick(interval)
+		for range ticker {
+			writeCSVRow(w, snapshotRecorderSample())
+			w.Flush()
+		}
+	}()
+	return nil
+}
+
+// recorderCSVMetaRow fills in the "os,cpu,gpu,ram,kernel" line. Only "os" and
+// "gpu" are known in this tree; the rest are left blank rather than faked.
+func recorderCSVMetaRow(sample RecorderSample) string {
+	labels := make([]string, 0, len(sample.GPUTemp))
+	for label := range sample.GPUTemp {
+		labels = append(labels, label)
+	}
+	return strings.Join([]string{runtime.GOOS, "", strings.Join(labels, "/"), "", ""}, ",")
+}
+
+func writeCSVRow(w *bufio.Writer, s RecorderSample) {
+	gpuLoad, gpuTemp := firstInt(s.GPULoad), firstInt(s.GPUTemp)
+	clock := firstClock(s.GPUClock)
+	mem := firstMem(s.GPUMem)
+	power := firstPower(s.GPUPower)
+	fmt.Fprintf(w, ",,,%d,,%d,%d,%d,%d,%.1f,,\n",
+		gpuLoad, gpuTemp, clock.CoreMHz, clock.MemMHz, mem.Used, power.Watts)
+}
+
+func startJSONLRecorder(f *os.File, interval time.Duration) error {
+	enc := json.NewEncoder(f)
+	go func() {
+		defer f.Close()
+		ticker := time.Tick(interval)
+		for range ticker {
+			enc.Encode(snapshotRecorderSample())
+		}
+	}()
+	return nil
+}
+
+func snapshotRecorderSample() RecorderSample {
+	gpuLoad := make(map[string]int)
+	for _, f := range cpuFuncs {
+		f(gpuLoad, false)
+	}
+	gpuTemp := make(map[string]int)
+	for _, f := range tempFuncs {
+		f(gpuTemp)
+	}
+	gpuMem := make(map[string]MemoryInfo)
+	for _, f := range memFuncs {
+		f(gpuMem)
+	}
+	gpuPower := make(map[string]PowerInfo)
+	for _, f := range powerFuncs {
+		f(gpuPower)
+	}
+	gpuClock := make(map[string]ClockInfo)
+	for _, f := range clockFuncs {
+		f(gpuClock)
+	}
+	gpuFan := make(map[string]FanInfo)
+	for _, f := range fanFuncs {
+		f(gpuFan)
+	}
+	gpuPCIe := make(map[string]PCIeInfo)
+	for _, f := range pcieFuncs {
+		f(gpuPCIe)
+	}
+	return RecorderSample{
+		Time:     time.Now(),
+		GPULoad:  gpuLoad,
+		GPUTemp:  gpuTemp,
+		GPUMem:   gpuMem,
+		GPUPower: gpuPower,
+		GPUClock: gpuClock,
+		GPUFan:   gpuFan,
+		GPUPCIe:  gpuPCIe,
+	}
+}
+
+func firstInt(m map[string]int) int {
+	for _, v := range m {
+		return v
+	}
+	return 0
+}
+
+func firstClock(m map[string]ClockInfo) ClockInfo {
+	for _, v := range m {
+		return v
+	}
+	return ClockInfo{}
+}
+
+func firstMem(m map[string]MemoryInfo) MemoryInfo {
+	for _, v := range m {
+		return v
+	}
+	return MemoryInfo{}
+}
+
+func firstPower(m map[string]PowerInfo) PowerInfo {
+	for _, v := range m {
+		return v
+	}
+	return PowerInfo{}
+}
@@ -0,0 +1,240 @@
+//go:build linux
+// +build linux
+
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LinkType classifies the PCIe (or NVLink) path between two GPUs, using the
+// same taxonomy NVML reports for nvmlGpuTopologyLevel.
+type LinkType int
+
+const (
+	LinkUnknown LinkType = iota
+	LinkSameBoard
+	LinkSingleSwitch
+	LinkMultiSwitch
+	LinkHostBridge
+	LinkSameCPU
+	LinkCrossCPU
+)
+
+func (l LinkType) String() string {
+	switch l {
+	case LinkSameBoard:
+		return "SameBoard"
+	case LinkSingleSwitch:
+		return "SingleSwitch"
+	case LinkMultiSwitch:
+		return "MultiSwitch"
+	case LinkHostBridge:
+		return "HostBridge"
+	case LinkSameCPU:
+		return "SameCPU"
+	case LinkCrossCPU:
+		return "CrossCPU"
+	default:
+		return "Unknown"
+	}
+}
+
+// TopologyMatrix is the NxN link-type grid between every discovered GPU,
+// indexed in the same order as Labels.
+type TopologyMatrix struct {
+	Labels []string
+	Links  [][]LinkType
+}
+
+// String renders the matrix as an ASCII grid, e.g. for a widget that wants a
+// quick-look table without reimplementing the layout.
+func (m TopologyMatrix) String() string {
+	var b strings.Builder
+	for _, row := range m.Links {
+		cells := make([]string, len(row))
+		for i, l := range row {
+			cells[i] = l.String()
+		}
+		b.WriteString(strings.Join(cells, "\t"))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+type topologyDevice struct {
+	label string
+	slot  string
+}
+
+// nvidiaTopologyDevices and nvidiaTopologyLink are filled in by
+// nvidia_linux.go's init when built with cgo, so this file stays buildable
+// without a CUDA/NVML toolchain present. Nil means "no NVIDIA devices to
+// consider".
+var (
+	nvidiaTopologyDevices func() ([]topologyDevice, error)
+	nvidiaTopologyLink    func(slotA, slotB string) LinkType
+)
+
+// Topology discovers every AMD and NVIDIA GPU visible to gotop and classifies
+// the PCIe link between every pair, useful on multi-GPU hosts (MI250/MI300,
+// multi-A100) where P2P locality drives scheduling decisions.
+func Topology() (TopologyMatrix, error) {
+	var devs []topologyDevice
+
+	amdGPUs, err := discoverAMDGPUs()
+	if err == nil {
+		for _, gpu := range amdGPUs {
+			devs = append(devs, topologyDevice{label: gpu.name, slot: pciSlotName(gpu.devicePath)})
+		}
+	}
+
+	if nvidiaTopologyDevices != nil {
+		nvDevs, err := nvidiaTopologyDevices()
+		if err == nil {
+			devs = append(devs, nvDevs...)
+		}
+	}
+
+	labels := make([]string, len(devs))
+	links := make([][]LinkType, len(devs))
+	for i := range devs {
+		labels[i] = devs[i].label
+		links[i] = make([]LinkType, len(devs))
+	}
+
+	for i := range devs {
+		for j := range devs {
+			if i == j {
+				links[i][j] = LinkSameBoard
+				continue
+			}
+			links[i][j] = linkBetween(devs[i], devs[j])
+		}
+	}
+
+	return TopologyMatrix{Labels: labels, Links: links}, nil
+}
+
+func linkBetween(a, b topologyDevice) LinkType {
+	if a.slot == "" || b.slot == "" {
+		return LinkUnknown
+	}
+	if nvidiaTopologyLink != nil {
+		if l := nvidiaTopologyLink(a.slot, b.slot); l != LinkUnknown {
+			return l
+		}
+	}
+	return classifyPCIeLink(a.slot, b.slot)
+}
+
+// classifyPCIeLink derives a LinkType for two AMD (or mixed-vendor) PCI
+// devices by walking the PCIe parent chain until the devices share an
+// ancestor, then classifying by the ancestor's PCI class (bridge vs root
+// complex) and by NUMA node.
+func classifyPCIeLink(slotA, slotB string) LinkType {
+	if slotA == slotB {
+		return LinkSameBoard
+	}
+
+	pathA, errA := pciAncestorChain(slotA)
+	pathB, errB := pciAncestorChain(slotB)
+	if errA != nil || errB != nil || len(pathA) == 0 || len(pathB) == 0 {
+		return LinkUnknown
+	}
+
+	ancestor, depthA, depthB := commonAncestor(pathA, pathB)
+	if ancestor == "" {
+		return LinkUnknown
+	}
+
+	if isRootComplex(ancestor) {
+		// The devices don't share a PCIe switch at all: the path goes all
+		// the way up to the host bridge/root complex. Use NUMA node to tell
+		// same-CPU locality from a cross-CPU (e.g. multi-socket) hop; a
+		// shallow common root with a known, matching NUMA node reads as
+		// HostBridge, a deeper one as the farther SameCPU/NODE level.
+		numaA := readNUMANode(slotA)
+		numaB := readNUMANode(slotB)
+		switch {
+		case numaA < 0 || numaB < 0 || numaA != numaB:
+			return LinkCrossCPU
+		case depthA <= 2 && depthB <= 2:
+			return LinkHostBridge
+		default:
+			return LinkSameCPU
+		}
+	}
+
+	// Ancestor is a PCI-to-PCI bridge (class 0x0604xx): the devices sit
+	// behind shared PCIe switch fabric. One intervening bridge on each side
+	// means they're one hop from the same switch; more means the path
+	// crosses multiple cascaded switches.
+	if depthA <= 1 && depthB <= 1 {
+		return LinkSingleSwitch
+	}
+	return LinkMultiSwitch
+}
+
+// pciAncestorChain returns the device's directory and every parent directory
+// up to (and including) the root, closest-first.
+func pciAncestorChain(slot string) ([]string, error) {
+	start, err := filepath.EvalSymlinks(filepath.Join("/sys/bus/pci/devices", slot))
+	if err != nil {
+		return nil, err
+	}
+	chain := []string{start}
+	dir := start
+	for i := 0; i < 32; i++ {
+		parent := filepath.Dir(dir)
+		if parent == dir || parent == "/" || parent == "." {
+			break
+		}
+		if _, err := os.Stat(filepath.Join(parent, "class")); err != nil {
+			break
+		}
+		chain = append(chain, parent)
+		dir = parent
+	}
+	return chain, nil
+}
+
+// commonAncestor finds the first directory shared by both ancestor chains
+// and returns it along with how many hops each device is below it.
+func commonAncestor(a, b []string) (string, int, int) {
+	bIndex := make(map[string]int, len(b))
+	for i, p := range b {
+		bIndex[p] = i
+	}
+	for i, p := range a {
+		if j, ok := bIndex[p]; ok {
+			return p, i, j
+		}
+	}
+	return "", 0, 0
+}
+
+func isRootComplex(devicePath string) bool {
+	class, err := readHexInt(filepath.Join(devicePath, "class"))
+	if err != nil {
+		return false
+	}
+	// PCI class codes are 24 bits (base class, subclass, interface); a host
+	// bridge is 0x0600xx, a PCI-to-PCI bridge is 0x0604xx.
+	return (class >> 8) == 0x0600
+}
+
+func readNUMANode(slot string) int {
+	data, err := os.ReadFile(filepath.Join("/sys/bus/pci/devices", slot, "numa_node"))
+	if err != nil {
+		return -1
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return -1
+	}
+	return n
+}